@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// defaultPageSize is the page size requested from Bitbucket Cloud's
+// paginated endpoints when CloudClient.PageSize is left unset.
+const defaultPageSize = 100
+
+// CloudClient talks to the public Bitbucket Cloud API
+// (https://api.bitbucket.org/2.0) and implements Forge.
+type CloudClient struct {
+	BaseURL   string
+	AuthToken string
+	Client    *resty.Client
+	Workspace string
+
+	// PageSize controls the `pagelen` requested from paginated endpoints.
+	// Defaults to defaultPageSize when left at zero.
+	PageSize int
+}
+
+// NewCloudClient creates a new CloudClient instance.
+func NewCloudClient(workspace, authToken string) *CloudClient {
+	client := resty.New().
+		SetRetryCount(5).
+		SetRetryWaitTime(1 * time.Second).
+		SetRetryMaxWaitTime(30 * time.Second).
+		AddRetryCondition(func(resp *resty.Response, err error) bool {
+			if err != nil {
+				return true
+			}
+			return resp.StatusCode() == http.StatusTooManyRequests || resp.StatusCode() == http.StatusServiceUnavailable
+		}).
+		SetRetryAfter(func(c *resty.Client, resp *resty.Response) (time.Duration, error) {
+			wait := retryAfterOrDefault(resp, 1*time.Second)
+			if wait <= 0 {
+				wait = 1 * time.Second
+			}
+			// add up to 20% jitter so a burst of throttled requests doesn't
+			// retry in lockstep
+			jitter := time.Duration(rand.Int63n(int64(wait) / 5))
+			return wait + jitter, nil
+		})
+
+	return &CloudClient{
+		BaseURL:   "https://api.bitbucket.org/2.0",
+		AuthToken: authToken,
+		Client:    client,
+		Workspace: workspace,
+		PageSize:  defaultPageSize,
+	}
+}
+
+// retryAfterOrDefault parses the Retry-After header (in seconds) off resp,
+// falling back to def when it's absent or malformed.
+func retryAfterOrDefault(resp *resty.Response, def time.Duration) time.Duration {
+	if resp == nil {
+		return def
+	}
+	header := resp.Header().Get("Retry-After")
+	if header == "" {
+		return def
+	}
+	seconds, err := strconv.Atoi(header)
+	if err != nil {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// pageSize returns c.PageSize, defaulting to defaultPageSize when unset.
+func (c *CloudClient) pageSize() int {
+	if c.PageSize <= 0 {
+		return defaultPageSize
+	}
+	return c.PageSize
+}
+
+// cloudRepository is the subset of Bitbucket Cloud's repository object that
+// this tool cares about.
+type cloudRepository struct {
+	Slug       string `json:"slug"`
+	Name       string `json:"name"`
+	MainBranch struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+}
+
+// cloudBranch is the subset of Bitbucket Cloud's branch object that this
+// tool cares about.
+type cloudBranch struct {
+	Name   string `json:"name"`
+	Target struct {
+		Hash   string    `json:"hash"`
+		Date   time.Time `json:"date"`
+		Author struct {
+			Raw  string `json:"raw"`
+			User struct {
+				DisplayName string `json:"display_name"`
+			} `json:"user"`
+		} `json:"author"`
+	} `json:"target"`
+}
+
+// author returns the best available identifier for who made the commit:
+// the linked Bitbucket account's display name, falling back to the raw
+// "Name <email>" string when the commit author isn't a Bitbucket user.
+func (b cloudBranch) author() string {
+	if b.Target.Author.User.DisplayName != "" {
+		return b.Target.Author.User.DisplayName
+	}
+	return b.Target.Author.Raw
+}
+
+// cloudPage is Bitbucket Cloud's standard paginated response envelope:
+// https://developer.atlassian.com/cloud/bitbucket/rest/intro/#pagination
+type cloudPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+// FetchRepositories fetches all repositories from the Bitbucket workspace,
+// following the `next` link until every page has been read.
+func (c *CloudClient) FetchRepositories() ([]Repository, error) {
+	var repos []Repository
+
+	url := fmt.Sprintf("%s/repositories/%s?pagelen=%d", c.BaseURL, c.Workspace, c.pageSize())
+	for url != "" {
+		var page cloudPage[cloudRepository]
+		resp, err := c.Client.R().
+			SetAuthToken(c.AuthToken).
+			SetResult(&page).
+			Get(url)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode() != 200 {
+			return nil, fmt.Errorf("failed to fetch repositories: %s", resp.Status())
+		}
+
+		for _, r := range page.Values {
+			repos = append(repos, Repository{
+				Slug:       r.Slug,
+				Name:       r.Name,
+				Project:    c.Workspace,
+				MainBranch: r.MainBranch.Name,
+			})
+		}
+		url = page.Next
+	}
+
+	return repos, nil
+}
+
+// FetchBranches fetches branches for a specific repository, following the
+// `next` link until every page has been read.
+func (c *CloudClient) FetchBranches(repoSlug string) ([]Branch, error) {
+	var branches []Branch
+
+	url := fmt.Sprintf("%s/repositories/%s/%s/refs/branches?pagelen=%d", c.BaseURL, c.Workspace, repoSlug, c.pageSize())
+	for url != "" {
+		var page cloudPage[cloudBranch]
+		resp, err := c.Client.R().
+			SetAuthToken(c.AuthToken).
+			SetResult(&page).
+			Get(url)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode() != 200 {
+			return nil, fmt.Errorf("failed to fetch branches for repo %s: %s", repoSlug, resp.Status())
+		}
+
+		for _, b := range page.Values {
+			branches = append(branches, Branch{
+				Name:           b.Name,
+				LastCommitHash: b.Target.Hash,
+				LastCommitDate: b.Target.Date,
+				Author:         b.author(),
+			})
+		}
+		url = page.Next
+	}
+
+	return branches, nil
+}
+
+// LastCommitDate returns the commit date already embedded in the branch
+// listing response, so no extra request is needed for Bitbucket Cloud.
+func (c *CloudClient) LastCommitDate(repoSlug, branchName string) (time.Time, error) {
+	branches, err := c.FetchBranches(repoSlug)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, b := range branches {
+		if b.Name == branchName {
+			return b.LastCommitDate, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("branch %s not found in repo %s", branchName, repoSlug)
+}
+
+// cloudBranchRestriction is the subset of a Bitbucket Cloud branch
+// restriction object that names the branches it applies to.
+type cloudBranchRestriction struct {
+	Pattern         string `json:"pattern"`
+	BranchMatchKind string `json:"branch_match_kind"`
+}
+
+// ListProtectedBranches returns the branch name/glob patterns that
+// Bitbucket Cloud's own branch restrictions API enforces for repoSlug, so
+// they can be merged with the user's configured protected_branches.
+func (c *CloudClient) ListProtectedBranches(repoSlug string) ([]string, error) {
+	var patterns []string
+
+	url := fmt.Sprintf("%s/repositories/%s/%s/branch-restrictions?pagelen=%d", c.BaseURL, c.Workspace, repoSlug, c.pageSize())
+	for url != "" {
+		var page cloudPage[cloudBranchRestriction]
+		resp, err := c.Client.R().
+			SetAuthToken(c.AuthToken).
+			SetResult(&page).
+			Get(url)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode() != 200 {
+			return nil, fmt.Errorf("failed to fetch branch restrictions for repo %s: %s", repoSlug, resp.Status())
+		}
+
+		for _, r := range page.Values {
+			if r.Pattern != "" {
+				patterns = append(patterns, r.Pattern)
+			}
+		}
+		url = page.Next
+	}
+
+	return patterns, nil
+}
+
+// HasOpenPullRequest reports whether any open pull request has branchName
+// as its source branch.
+func (c *CloudClient) HasOpenPullRequest(repoSlug, branchName string) (bool, error) {
+	var page cloudPage[struct {
+		ID int64 `json:"id"`
+	}]
+	resp, err := c.Client.R().
+		SetAuthToken(c.AuthToken).
+		SetQueryParams(map[string]string{
+			"q": fmt.Sprintf(`source.branch.name="%s" AND state="OPEN"`, branchName),
+		}).
+		SetResult(&page).
+		Get(fmt.Sprintf("%s/repositories/%s/%s/pullrequests", c.BaseURL, c.Workspace, repoSlug))
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode() != 200 {
+		return false, fmt.Errorf("failed to fetch open pull requests for branch %s in repo %s: %s", branchName, repoSlug, resp.Status())
+	}
+
+	return len(page.Values) > 0, nil
+}
+
+// CommitsAheadOfDefault reports how many commits on branchName are not
+// reachable from defaultBranch.
+func (c *CloudClient) CommitsAheadOfDefault(repoSlug, branchName, defaultBranch string) (int, error) {
+	ahead := 0
+
+	url := fmt.Sprintf("%s/repositories/%s/%s/commits/%s?exclude=%s&pagelen=%d", c.BaseURL, c.Workspace, repoSlug, branchName, defaultBranch, c.pageSize())
+	for url != "" {
+		var page cloudPage[struct {
+			Hash string `json:"hash"`
+		}]
+		resp, err := c.Client.R().
+			SetAuthToken(c.AuthToken).
+			SetResult(&page).
+			Get(url)
+		if err != nil {
+			return 0, err
+		}
+		if resp.StatusCode() != 200 {
+			return 0, fmt.Errorf("failed to fetch commits ahead of %s for branch %s in repo %s: %s", defaultBranch, branchName, repoSlug, resp.Status())
+		}
+
+		ahead += len(page.Values)
+		url = page.Next
+	}
+
+	return ahead, nil
+}
+
+// NotifyStaleBranch posts message as a comment on branch's last commit.
+func (c *CloudClient) NotifyStaleBranch(repoSlug string, branch Branch, message string) error {
+	resp, err := c.Client.R().
+		SetAuthToken(c.AuthToken).
+		SetBody(map[string]interface{}{
+			"content": map[string]string{"raw": message},
+		}).
+		Post(fmt.Sprintf("%s/repositories/%s/%s/commit/%s/comments", c.BaseURL, c.Workspace, repoSlug, branch.LastCommitHash))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != 201 {
+		return fmt.Errorf("failed to post stale-branch notification on %s in repo %s: %s", branch.Name, repoSlug, resp.Status())
+	}
+	return nil
+}
+
+// DeleteBranch deletes a specific branch in a repository. Callers are
+// responsible for checking branch protection before calling this, since
+// that's now driven by user Config rather than a hardcoded list.
+func (c *CloudClient) DeleteBranch(repoSlug, branchName string) error {
+	resp, err := c.Client.R().
+		SetAuthToken(c.AuthToken).
+		Delete(fmt.Sprintf("%s/repositories/%s/%s/refs/branches/%s", c.BaseURL, c.Workspace, repoSlug, branchName))
+
+	if err != nil {
+		return err
+	}
+
+	// Check for successful response
+	if resp.StatusCode() != 204 {
+		return fmt.Errorf("failed to delete branch %s: %s", branchName, resp.Status())
+	}
+
+	return nil
+}
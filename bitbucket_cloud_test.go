@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCloudFetchRepositoriesPaginates(t *testing.T) {
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repositories/ws", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"values": []map[string]interface{}{
+					{"slug": "repo-b", "name": "Repo B"},
+				},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"values": []map[string]interface{}{
+				{"slug": "repo-a", "name": "Repo A"},
+			},
+			"next": server.URL + "/repositories/ws?page=2",
+		})
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewCloudClient("ws", "tok")
+	client.BaseURL = server.URL
+
+	repos, err := client.FetchRepositories()
+	if err != nil {
+		t.Fatalf("FetchRepositories() error = %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("got %d repos, want 2 (one per page)", len(repos))
+	}
+	if repos[0].Slug != "repo-a" || repos[1].Slug != "repo-b" {
+		t.Errorf("repos = %+v, want slugs repo-a and repo-b", repos)
+	}
+}
+
+func TestCloudFetchBranchesPaginates(t *testing.T) {
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repositories/ws/repo/refs/branches", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"values": []map[string]interface{}{
+					{"name": "release/1.0"},
+				},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"values": []map[string]interface{}{
+				{"name": "main"},
+			},
+			"next": server.URL + "/repositories/ws/repo/refs/branches?page=2",
+		})
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewCloudClient("ws", "tok")
+	client.BaseURL = server.URL
+
+	branches, err := client.FetchBranches("repo")
+	if err != nil {
+		t.Fatalf("FetchBranches() error = %v", err)
+	}
+	if len(branches) != 2 {
+		t.Fatalf("got %d branches, want 2 (one per page)", len(branches))
+	}
+	if branches[0].Name != "main" || branches[1].Name != "release/1.0" {
+		t.Errorf("branches = %+v, want names main and release/1.0", branches)
+	}
+}
+
+func TestCloudClientRetriesOn429(t *testing.T) {
+	var attempts int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repositories/ws", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"values": []map[string]interface{}{{"slug": "repo-a"}}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewCloudClient("ws", "tok")
+	client.BaseURL = server.URL
+
+	repos, err := client.FetchRepositories()
+	if err != nil {
+		t.Fatalf("FetchRepositories() error = %v", err)
+	}
+	if len(repos) != 1 {
+		t.Fatalf("got %d repos, want 1 after retrying past the 429", len(repos))
+	}
+	if atomic.LoadInt32(&attempts) < 2 {
+		t.Errorf("got %d attempts, want at least 2 (the throttled request plus a retry)", attempts)
+	}
+}
@@ -0,0 +1,413 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// DataCenterConfig holds the connection settings for an on-premises
+// Bitbucket Data Center/Server instance.
+type DataCenterConfig struct {
+	BaseURL  string // e.g. https://bitbucket.example.com
+	Username string
+	Token    string // personal access token or service-account password
+
+	OAuthClientID     string
+	OAuthClientSecret string
+
+	SkipTLSVerify bool
+
+	Projects []string // project keys to scan
+}
+
+// DataCenterClient talks to a Bitbucket Data Center/Server instance's REST
+// API (/rest/api/1.0, /rest/branch-utils/1.0) and implements Forge.
+//
+// Repository slugs returned by FetchRepositories are namespaced as
+// "PROJECT/repo-slug" since, unlike Bitbucket Cloud, Data Center scopes
+// repositories by project key rather than by a single workspace.
+type DataCenterClient struct {
+	BaseURL  string
+	Client   *resty.Client
+	Projects []string
+}
+
+// NewDataCenterClient creates a new DataCenterClient from cfg, configuring
+// HTTP basic auth (personal access token) or an OAuth2 client-credentials
+// token, and optionally skipping TLS verification for self-signed hosts.
+// Returns an error if an OAuth2 token was requested but couldn't be
+// obtained, rather than silently building an unauthenticated client.
+func NewDataCenterClient(cfg DataCenterConfig) (*DataCenterClient, error) {
+	client := resty.New()
+
+	if cfg.SkipTLSVerify {
+		client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	}
+
+	switch {
+	case cfg.OAuthClientID != "" && cfg.OAuthClientSecret != "":
+		client.SetRetryCount(0)
+		token, err := fetchOAuthToken(client, cfg.BaseURL, cfg.OAuthClientID, cfg.OAuthClientSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain OAuth2 token: %w", err)
+		}
+		client.SetAuthToken(token)
+	case cfg.Token != "":
+		client.SetBasicAuth(cfg.Username, cfg.Token)
+	}
+
+	return &DataCenterClient{
+		BaseURL:  strings.TrimRight(cfg.BaseURL, "/"),
+		Client:   client,
+		Projects: cfg.Projects,
+	}, nil
+}
+
+// fetchOAuthToken performs the OAuth2 client-credentials flow against the
+// Data Center instance's built-in OAuth2 provider.
+func fetchOAuthToken(client *resty.Client, baseURL, clientID, clientSecret string) (string, error) {
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	resp, err := client.R().
+		SetBasicAuth(clientID, clientSecret).
+		SetFormData(map[string]string{"grant_type": "client_credentials"}).
+		SetResult(&result).
+		Post(fmt.Sprintf("%s/rest/oauth2/latest/token", strings.TrimRight(baseURL, "/")))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode() != 200 {
+		return "", fmt.Errorf("failed to obtain OAuth2 token: %s", resp.Status())
+	}
+	return result.AccessToken, nil
+}
+
+// dcRepository is the subset of the Data Center repository object that this
+// tool cares about.
+type dcRepository struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// dcBranch is the subset of the Data Center branch-utils branch object that
+// this tool cares about.
+type dcBranch struct {
+	DisplayID  string `json:"displayId"`
+	LatestHash string `json:"latestCommit"`
+	IsDefault  bool   `json:"isDefault"`
+}
+
+// splitProjectSlug splits a "PROJECT/repo-slug" identifier back into its
+// project key and repo slug.
+func splitProjectSlug(repoSlug string) (project, slug string, err error) {
+	parts := strings.SplitN(repoSlug, "/", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("expected repo slug in PROJECT/repo form, got %q", repoSlug)
+	}
+	return parts[0], parts[1], nil
+}
+
+// FetchRepositories fetches all repositories across the configured
+// project keys, following Data Center's start/limit pagination.
+func (d *DataCenterClient) FetchRepositories() ([]Repository, error) {
+	var repos []Repository
+
+	for _, project := range d.Projects {
+		start := 0
+		for {
+			var page struct {
+				Values        []dcRepository `json:"values"`
+				IsLastPage    bool           `json:"isLastPage"`
+				NextPageStart int            `json:"nextPageStart"`
+			}
+			resp, err := d.Client.R().
+				SetQueryParams(map[string]string{
+					"start": strconv.Itoa(start),
+					"limit": "100",
+				}).
+				SetResult(&page).
+				Get(fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos", d.BaseURL, project))
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode() != 200 {
+				return nil, fmt.Errorf("failed to fetch repositories for project %s: %s", project, resp.Status())
+			}
+
+			for _, r := range page.Values {
+				mainBranch, err := d.defaultBranch(project, r.Slug)
+				if err != nil {
+					return nil, err
+				}
+				repos = append(repos, Repository{
+					Slug:       fmt.Sprintf("%s/%s", project, r.Slug),
+					Name:       r.Name,
+					Project:    project,
+					MainBranch: mainBranch,
+				})
+			}
+
+			if page.IsLastPage {
+				break
+			}
+			start = page.NextPageStart
+		}
+	}
+
+	return repos, nil
+}
+
+// defaultBranch looks up the repository's configured default branch.
+func (d *DataCenterClient) defaultBranch(project, slug string) (string, error) {
+	var result struct {
+		DisplayID string `json:"displayId"`
+	}
+	resp, err := d.Client.R().
+		SetResult(&result).
+		Get(fmt.Sprintf("%s/rest/branch-utils/1.0/projects/%s/repos/%s/branches/default", d.BaseURL, project, slug))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode() != 200 {
+		// Not every repo has a default branch configured (e.g. empty repos).
+		return "", nil
+	}
+	return result.DisplayID, nil
+}
+
+// FetchBranches fetches branches for a specific repository. repoSlug must
+// be in "PROJECT/repo-slug" form, as returned by FetchRepositories.
+func (d *DataCenterClient) FetchBranches(repoSlug string) ([]Branch, error) {
+	project, slug, err := splitProjectSlug(repoSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []Branch
+	start := 0
+	for {
+		var page struct {
+			Values        []dcBranch `json:"values"`
+			IsLastPage    bool       `json:"isLastPage"`
+			NextPageStart int        `json:"nextPageStart"`
+		}
+		resp, err := d.Client.R().
+			SetQueryParams(map[string]string{
+				"start": strconv.Itoa(start),
+				"limit": "100",
+			}).
+			SetResult(&page).
+			Get(fmt.Sprintf("%s/rest/branch-utils/1.0/projects/%s/repos/%s/branches", d.BaseURL, project, slug))
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode() != 200 {
+			return nil, fmt.Errorf("failed to fetch branches for repo %s: %s", repoSlug, resp.Status())
+		}
+
+		for _, b := range page.Values {
+			commit, err := d.lastCommit(repoSlug, b.DisplayID)
+			if err != nil {
+				return nil, err
+			}
+			branches = append(branches, Branch{
+				Name:           b.DisplayID,
+				LastCommitHash: b.LatestHash,
+				LastCommitDate: commit.date(),
+				Author:         commit.AuthorName,
+			})
+		}
+
+		if page.IsLastPage {
+			break
+		}
+		start = page.NextPageStart
+	}
+
+	return branches, nil
+}
+
+// dcCommit is the subset of a Data Center commit object that this tool
+// cares about.
+type dcCommit struct {
+	ID              string `json:"id"`
+	AuthorTimestamp int64  `json:"authorTimestamp"`
+	AuthorName      string `json:"-"`
+}
+
+func (c dcCommit) date() time.Time {
+	return time.UnixMilli(c.AuthorTimestamp)
+}
+
+// lastCommit fetches the most recent commit reachable from branchName.
+func (d *DataCenterClient) lastCommit(repoSlug, branchName string) (dcCommit, error) {
+	project, slug, err := splitProjectSlug(repoSlug)
+	if err != nil {
+		return dcCommit{}, err
+	}
+
+	var page struct {
+		Values []struct {
+			ID              string `json:"id"`
+			AuthorTimestamp int64  `json:"authorTimestamp"`
+			Author          struct {
+				Name string `json:"name"`
+			} `json:"author"`
+		} `json:"values"`
+	}
+	resp, err := d.Client.R().
+		SetQueryParams(map[string]string{
+			"until": branchName,
+			"limit": "1",
+		}).
+		SetResult(&page).
+		Get(fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/commits", d.BaseURL, project, slug))
+	if err != nil {
+		return dcCommit{}, err
+	}
+	if resp.StatusCode() != 200 {
+		return dcCommit{}, fmt.Errorf("failed to fetch last commit for branch %s in repo %s: %s", branchName, repoSlug, resp.Status())
+	}
+	if len(page.Values) == 0 {
+		return dcCommit{}, fmt.Errorf("branch %s in repo %s has no commits", branchName, repoSlug)
+	}
+
+	v := page.Values[0]
+	return dcCommit{ID: v.ID, AuthorTimestamp: v.AuthorTimestamp, AuthorName: v.Author.Name}, nil
+}
+
+// LastCommitDate returns the timestamp of the most recent commit reachable
+// from branchName. The branch-utils listing endpoint doesn't include a
+// commit date, so this issues a separate, single-commit history request.
+func (d *DataCenterClient) LastCommitDate(repoSlug, branchName string) (time.Time, error) {
+	commit, err := d.lastCommit(repoSlug, branchName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return commit.date(), nil
+}
+
+// HasOpenPullRequest reports whether any open pull request has branchName
+// as its source branch.
+func (d *DataCenterClient) HasOpenPullRequest(repoSlug, branchName string) (bool, error) {
+	project, slug, err := splitProjectSlug(repoSlug)
+	if err != nil {
+		return false, err
+	}
+
+	var page struct {
+		Values []struct {
+			ID int64 `json:"id"`
+		} `json:"values"`
+	}
+	resp, err := d.Client.R().
+		SetQueryParams(map[string]string{
+			"at":    "refs/heads/" + branchName,
+			"state": "OPEN",
+		}).
+		SetResult(&page).
+		Get(fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests", d.BaseURL, project, slug))
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode() != 200 {
+		return false, fmt.Errorf("failed to fetch open pull requests for branch %s in repo %s: %s", branchName, repoSlug, resp.Status())
+	}
+
+	return len(page.Values) > 0, nil
+}
+
+// CommitsAheadOfDefault reports how many commits on branchName are not
+// reachable from defaultBranch.
+func (d *DataCenterClient) CommitsAheadOfDefault(repoSlug, branchName, defaultBranch string) (int, error) {
+	project, slug, err := splitProjectSlug(repoSlug)
+	if err != nil {
+		return 0, err
+	}
+
+	ahead := 0
+	start := 0
+	for {
+		var page struct {
+			Values        []struct{} `json:"values"`
+			IsLastPage    bool       `json:"isLastPage"`
+			NextPageStart int        `json:"nextPageStart"`
+		}
+		resp, err := d.Client.R().
+			SetQueryParams(map[string]string{
+				"from":  defaultBranch,
+				"to":    branchName,
+				"start": strconv.Itoa(start),
+				"limit": "100",
+			}).
+			SetResult(&page).
+			Get(fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/compare/commits", d.BaseURL, project, slug))
+		if err != nil {
+			return 0, err
+		}
+		if resp.StatusCode() != 200 {
+			return 0, fmt.Errorf("failed to compare branch %s against %s in repo %s: %s", branchName, defaultBranch, repoSlug, resp.Status())
+		}
+
+		ahead += len(page.Values)
+		if page.IsLastPage {
+			break
+		}
+		start = page.NextPageStart
+	}
+
+	return ahead, nil
+}
+
+// NotifyStaleBranch posts message as a comment on branch's last commit.
+func (d *DataCenterClient) NotifyStaleBranch(repoSlug string, branch Branch, message string) error {
+	project, slug, err := splitProjectSlug(repoSlug)
+	if err != nil {
+		return err
+	}
+
+	resp, err := d.Client.R().
+		SetBody(map[string]string{"text": message}).
+		Post(fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/commits/%s/comments", d.BaseURL, project, slug, branch.LastCommitHash))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != 200 && resp.StatusCode() != 201 {
+		return fmt.Errorf("failed to post stale-branch notification on %s in repo %s: %s", branch.Name, repoSlug, resp.Status())
+	}
+	return nil
+}
+
+// DeleteBranch deletes a specific branch in a repository. Callers are
+// responsible for checking branch protection before calling this, since
+// that's now driven by user Config rather than a hardcoded list.
+func (d *DataCenterClient) DeleteBranch(repoSlug, branchName string) error {
+	project, slug, err := splitProjectSlug(repoSlug)
+	if err != nil {
+		return err
+	}
+
+	resp, err := d.Client.R().
+		SetHeader("Content-Type", "application/vnd.atl.bitbucket.bulk+json").
+		SetBody(map[string]interface{}{
+			"name":   "refs/heads/" + branchName,
+			"dryRun": false,
+		}).
+		Delete(fmt.Sprintf("%s/rest/branch-utils/1.0/projects/%s/repos/%s/branches", d.BaseURL, project, slug))
+
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != 204 {
+		return fmt.Errorf("failed to delete branch %s: %s", branchName, resp.Status())
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDataCenterFetchRepositoriesPaginates(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/1.0/projects/PROJ/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("start") == "0" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"values":        []map[string]string{{"slug": "repo-a", "name": "Repo A"}},
+				"isLastPage":    false,
+				"nextPageStart": 1,
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"values":     []map[string]string{{"slug": "repo-b", "name": "Repo B"}},
+			"isLastPage": true,
+		})
+	})
+	mux.HandleFunc("/rest/branch-utils/1.0/projects/PROJ/repos/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/branches/default") {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"displayId": "main"})
+			return
+		}
+		http.NotFound(w, r)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewDataCenterClient(DataCenterConfig{BaseURL: server.URL, Token: "tok", Projects: []string{"PROJ"}})
+	if err != nil {
+		t.Fatalf("NewDataCenterClient() error = %v", err)
+	}
+
+	repos, err := client.FetchRepositories()
+	if err != nil {
+		t.Fatalf("FetchRepositories() error = %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("got %d repos, want 2 (one per page)", len(repos))
+	}
+	if repos[0].Slug != "PROJ/repo-a" || repos[1].Slug != "PROJ/repo-b" {
+		t.Errorf("repos = %+v, want slugs PROJ/repo-a and PROJ/repo-b", repos)
+	}
+	if repos[0].MainBranch != "main" {
+		t.Errorf("MainBranch = %q, want %q", repos[0].MainBranch, "main")
+	}
+}
+
+func TestNewDataCenterClientOAuthSuccess(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/oauth2/latest/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "fake-token"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewDataCenterClient(DataCenterConfig{
+		BaseURL:           server.URL,
+		OAuthClientID:     "id",
+		OAuthClientSecret: "secret",
+		Projects:          []string{"PROJ"},
+	})
+	if err != nil {
+		t.Fatalf("NewDataCenterClient() error = %v", err)
+	}
+	if client == nil {
+		t.Fatal("NewDataCenterClient() returned nil client with no error")
+	}
+}
+
+func TestNewDataCenterClientOAuthFailurePropagatesError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/oauth2/latest/token", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewDataCenterClient(DataCenterConfig{
+		BaseURL:           server.URL,
+		OAuthClientID:     "id",
+		OAuthClientSecret: "wrong-secret",
+		Projects:          []string{"PROJ"},
+	})
+	if err == nil {
+		t.Fatal("NewDataCenterClient() error = nil, want error when OAuth2 token request fails")
+	}
+	if client != nil {
+		t.Errorf("NewDataCenterClient() client = %+v, want nil on error", client)
+	}
+}
+
+func TestNewForgeFromEnvRequiresCredentials(t *testing.T) {
+	t.Setenv("BITBUCKET_KIND", "datacenter")
+	t.Setenv("BITBUCKET_URL", "https://bitbucket.example.com")
+	t.Setenv("BITBUCKET_PROJECTS", "PROJ")
+	t.Setenv("BITBUCKET_TOKEN", "")
+	t.Setenv("BITBUCKET_OAUTH_CLIENT_ID", "")
+	t.Setenv("BITBUCKET_OAUTH_CLIENT_SECRET", "")
+
+	if _, err := NewForgeFromEnv(); err == nil {
+		t.Fatal("NewForgeFromEnv() error = nil, want error when neither token nor OAuth credentials are set")
+	}
+}
+
+func TestNewForgeFromEnvUnsupportedKind(t *testing.T) {
+	t.Setenv("BITBUCKET_KIND", "sourcehut")
+
+	if _, err := NewForgeFromEnv(); err == nil {
+		t.Fatal("NewForgeFromEnv() error = nil, want error for unsupported BITBUCKET_KIND")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the user-configurable branch protection and include/exclude
+// filtering rules, loaded from a YAML file and overridable via environment
+// variables.
+type Config struct {
+	// ProtectedBranches lists glob or regex patterns (e.g. "release/*",
+	// "regex:^hotfix-\\d+$") naming branches that must never be reported
+	// as deletable, regardless of how stale they are.
+	ProtectedBranches []string `yaml:"protected_branches"`
+
+	// IncludeRepos / ExcludeRepos scope which repositories are scanned.
+	// A repo must match IncludeRepos (when non-empty) and must not match
+	// ExcludeRepos.
+	IncludeRepos []string `yaml:"include_repos"`
+	ExcludeRepos []string `yaml:"exclude_repos"`
+
+	// IncludeBranches / ExcludeBranches scope which branches within a
+	// scanned repo are considered, following the same include/exclude
+	// semantics as repos.
+	IncludeBranches []string `yaml:"include_branches"`
+	ExcludeBranches []string `yaml:"exclude_branches"`
+}
+
+// defaultConfig returns the protection defaults this tool has always
+// shipped with, used when no config file or env var overrides them.
+func defaultConfig() Config {
+	return Config{
+		ProtectedBranches: []string{"main", "master", "develop"},
+	}
+}
+
+// LoadConfigFromEnv builds a Config from the YAML file named by
+// CONFIG_FILE (default "config.yaml", silently skipped if it doesn't
+// exist), then applies any PROTECTED_BRANCHES / INCLUDE_REPOS /
+// EXCLUDE_REPOS / INCLUDE_BRANCHES / EXCLUDE_BRANCHES env var overrides on
+// top, following the same env-wins-over-file pattern as the rest of this
+// tool's configuration.
+func LoadConfigFromEnv() (Config, error) {
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		path = "config.yaml"
+	}
+
+	cfg := defaultConfig()
+	if data, err := os.ReadFile(path); err == nil {
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return Config{}, err
+		}
+	} else if !os.IsNotExist(err) {
+		return Config{}, err
+	}
+
+	applyConfigEnvOverrides(&cfg)
+	return cfg, nil
+}
+
+// applyConfigEnvOverrides overrides cfg's filter lists with comma-separated
+// env vars, when set.
+func applyConfigEnvOverrides(cfg *Config) {
+	if v := os.Getenv("PROTECTED_BRANCHES"); v != "" {
+		cfg.ProtectedBranches = splitAndTrim(v, ",")
+	}
+	if v := os.Getenv("INCLUDE_REPOS"); v != "" {
+		cfg.IncludeRepos = splitAndTrim(v, ",")
+	}
+	if v := os.Getenv("EXCLUDE_REPOS"); v != "" {
+		cfg.ExcludeRepos = splitAndTrim(v, ",")
+	}
+	if v := os.Getenv("INCLUDE_BRANCHES"); v != "" {
+		cfg.IncludeBranches = splitAndTrim(v, ",")
+	}
+	if v := os.Getenv("EXCLUDE_BRANCHES"); v != "" {
+		cfg.ExcludeBranches = splitAndTrim(v, ",")
+	}
+}
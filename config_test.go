@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigFromEnvDefaults(t *testing.T) {
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "does-not-exist.yaml"))
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv() error = %v", err)
+	}
+
+	want := defaultConfig()
+	if len(cfg.ProtectedBranches) != len(want.ProtectedBranches) {
+		t.Fatalf("ProtectedBranches = %v, want %v", cfg.ProtectedBranches, want.ProtectedBranches)
+	}
+}
+
+func TestLoadConfigFromEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeFile(t, path, `
+protected_branches:
+  - main
+  - release/*
+include_repos:
+  - team-a/*
+`)
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv() error = %v", err)
+	}
+
+	if got, want := cfg.ProtectedBranches, []string{"main", "release/*"}; !equalStrings(got, want) {
+		t.Errorf("ProtectedBranches = %v, want %v", got, want)
+	}
+	if got, want := cfg.IncludeRepos, []string{"team-a/*"}; !equalStrings(got, want) {
+		t.Errorf("IncludeRepos = %v, want %v", got, want)
+	}
+}
+
+func TestLoadConfigFromEnvOverridesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeFile(t, path, `
+protected_branches:
+  - main
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("PROTECTED_BRANCHES", "main, develop, release/*")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv() error = %v", err)
+	}
+
+	if got, want := cfg.ProtectedBranches, []string{"main", "develop", "release/*"}; !equalStrings(got, want) {
+		t.Errorf("ProtectedBranches = %v, want %v (env should win over file)", got, want)
+	}
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// matchesPattern reports whether name matches pattern. A pattern prefixed
+// with "regex:" is compiled and matched as a regular expression; anything
+// else is matched as a shell glob (e.g. "release/*", "hotfix/*").
+func matchesPattern(pattern, name string) bool {
+	if rx, ok := strings.CutPrefix(pattern, "regex:"); ok {
+		re, err := regexp.Compile(rx)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(name)
+	}
+
+	matched, err := filepath.Match(pattern, name)
+	return err == nil && matched
+}
+
+// matchesAny reports whether name matches any of patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if matchesPattern(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowed applies gickup-style include/exclude filtering: name is allowed
+// if it doesn't match any exclude pattern, and either include is empty or
+// name matches at least one include pattern.
+func isAllowed(name string, include, exclude []string) bool {
+	if matchesAny(exclude, name) {
+		return false
+	}
+	if len(include) == 0 {
+		return true
+	}
+	return matchesAny(include, name)
+}
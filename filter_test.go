@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestMatchesPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"main", "main", true},
+		{"main", "maine", false},
+		{"release/*", "release/1.0", true},
+		{"release/*", "release/1.0/hotfix", false},
+		{"hotfix/*", "feature/x", false},
+		{`regex:^hotfix-\d+$`, "hotfix-42", true},
+		{`regex:^hotfix-\d+$`, "hotfix-abc", false},
+		{"regex:(", "anything", false}, // invalid regex never matches
+	}
+
+	for _, c := range cases {
+		if got := matchesPattern(c.pattern, c.name); got != c.want {
+			t.Errorf("matchesPattern(%q, %q) = %v, want %v", c.pattern, c.name, got, c.want)
+		}
+	}
+}
+
+func TestIsAllowed(t *testing.T) {
+	cases := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    bool
+	}{
+		{"main", nil, nil, true},                                               // no filters: allowed
+		{"main", nil, []string{"main"}, false},                                 // excluded
+		{"feature/x", []string{"release/*"}, nil, false},                       // not in include list
+		{"release/1.0", []string{"release/*"}, nil, true},                      // matches include
+		{"release/1.0", []string{"release/*"}, []string{"release/1.0"}, false}, // exclude wins over include
+	}
+
+	for _, c := range cases {
+		if got := isAllowed(c.name, c.include, c.exclude); got != c.want {
+			t.Errorf("isAllowed(%q, %v, %v) = %v, want %v", c.name, c.include, c.exclude, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Repository is the forge-agnostic view of a repository, normalized from
+// either the Bitbucket Cloud or Data Center/Server API responses.
+type Repository struct {
+	Slug       string
+	Name       string
+	Project    string // workspace (Cloud) or project key (Data Center)
+	MainBranch string
+}
+
+// Branch is the forge-agnostic view of a branch.
+type Branch struct {
+	Name           string
+	LastCommitHash string
+	LastCommitDate time.Time
+	Author         string
+}
+
+// Forge is implemented by each supported Bitbucket flavor (Cloud, Data
+// Center/Server, ...) so the stale-branch scanning logic in stale.go never
+// has to know which one it's talking to.
+type Forge interface {
+	// FetchRepositories returns every repository in the configured
+	// workspace/project(s).
+	FetchRepositories() ([]Repository, error)
+
+	// FetchBranches returns every branch for the given repository.
+	FetchBranches(repoSlug string) ([]Branch, error)
+
+	// LastCommitDate returns the timestamp of the most recent commit on
+	// branchName. It is kept separate from FetchBranches because not every
+	// backend includes the commit date in the branch listing response.
+	LastCommitDate(repoSlug, branchName string) (time.Time, error)
+
+	// DeleteBranch deletes branchName in repoSlug.
+	DeleteBranch(repoSlug, branchName string) error
+
+	// HasOpenPullRequest reports whether any open pull request has
+	// branchName as its source branch.
+	HasOpenPullRequest(repoSlug, branchName string) (bool, error)
+
+	// CommitsAheadOfDefault reports how many commits on branchName are not
+	// reachable from defaultBranch, i.e. would be lost if branchName were
+	// deleted without merging.
+	CommitsAheadOfDefault(repoSlug, branchName, defaultBranch string) (int, error)
+
+	// NotifyStaleBranch posts message as a comment on branch's last commit,
+	// giving the branch owner a grace period before a future run deletes it.
+	NotifyStaleBranch(repoSlug string, branch Branch, message string) error
+}
+
+// BranchRestrictionLister is implemented by forges that can report
+// server-side branch restrictions, used to auto-detect protected branches
+// on top of whatever the user configured.
+type BranchRestrictionLister interface {
+	ListProtectedBranches(repoSlug string) ([]string, error)
+}
+
+// ForgeKind identifies which Forge implementation to construct.
+type ForgeKind string
+
+const (
+	ForgeCloud      ForgeKind = "cloud"
+	ForgeDataCenter ForgeKind = "datacenter"
+)
+
+// NewForgeFromEnv builds the Forge selected by BITBUCKET_KIND, reading the
+// rest of its configuration from environment variables so the same
+// stale-branch logic can run unmodified against Bitbucket Cloud or an
+// on-premises Data Center/Server instance.
+func NewForgeFromEnv() (Forge, error) {
+	kind := ForgeKind(os.Getenv("BITBUCKET_KIND"))
+	if kind == "" {
+		kind = ForgeCloud
+	}
+
+	switch kind {
+	case ForgeCloud:
+		workspace := os.Getenv("BITBUCKET_WORKSPACE")
+		if workspace == "" {
+			return nil, fmt.Errorf("BITBUCKET_WORKSPACE environment variable is not set")
+		}
+		authToken := os.Getenv("BITBUCKET_TOKEN")
+		if authToken == "" {
+			return nil, fmt.Errorf("BITBUCKET_TOKEN environment variable is not set")
+		}
+		return NewCloudClient(workspace, authToken), nil
+
+	case ForgeDataCenter:
+		baseURL := os.Getenv("BITBUCKET_URL")
+		if baseURL == "" {
+			return nil, fmt.Errorf("BITBUCKET_URL environment variable is not set")
+		}
+		projects := os.Getenv("BITBUCKET_PROJECTS")
+		if projects == "" {
+			return nil, fmt.Errorf("BITBUCKET_PROJECTS environment variable is not set")
+		}
+
+		cfg := DataCenterConfig{
+			BaseURL:           baseURL,
+			Username:          os.Getenv("BITBUCKET_USERNAME"),
+			Token:             os.Getenv("BITBUCKET_TOKEN"),
+			OAuthClientID:     os.Getenv("BITBUCKET_OAUTH_CLIENT_ID"),
+			OAuthClientSecret: os.Getenv("BITBUCKET_OAUTH_CLIENT_SECRET"),
+			SkipTLSVerify:     os.Getenv("BITBUCKET_SKIP_TLS_VERIFY") == "true",
+			Projects:          splitAndTrim(projects, ","),
+		}
+		if cfg.Token == "" && cfg.OAuthClientID == "" {
+			return nil, fmt.Errorf("either BITBUCKET_TOKEN (personal access token) or BITBUCKET_OAUTH_CLIENT_ID/BITBUCKET_OAUTH_CLIENT_SECRET must be set")
+		}
+		return NewDataCenterClient(cfg)
+
+	default:
+		return nil, fmt.Errorf("unsupported BITBUCKET_KIND %q: expected %q or %q", kind, ForgeCloud, ForgeDataCenter)
+	}
+}
@@ -1,184 +1,93 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"flag"
+	"log/slog"
 	"os"
+	"os/signal"
+	"runtime"
 	"time"
 
-	"github.com/go-resty/resty/v2"
 	"github.com/joho/godotenv"
 )
 
-// BitbucketClient represents a client to interact with the Bitbucket API using Resty
-type BitbucketClient struct {
-	BaseURL   string
-	AuthToken string
-	Client    *resty.Client
-	Workspace string
-}
-
-// NewBitbucketClient creates a new BitbucketClient instance
-func NewBitbucketClient(workspace, authToken string) *BitbucketClient {
-	client := resty.New()
-
-	return &BitbucketClient{
-		BaseURL:   "https://api.bitbucket.org/2.0",
-		AuthToken: authToken,
-		Client:    client,
-		Workspace: workspace,
-	}
-}
-
-// FetchRepositories fetches all repositories from the Bitbucket workspace
-func (b *BitbucketClient) FetchRepositories() ([]map[string]interface{}, error) {
-	repos := []map[string]interface{}{}
-	resp, err := b.Client.R().
-		SetAuthToken(b.AuthToken).
-		SetResult(&repos).
-		Get(fmt.Sprintf("%s/repositories/%s", b.BaseURL, b.Workspace))
+func main() {
+	deleteFlag := flag.Bool("delete", false, "delete stale branches instead of only reporting them")
+	force := flag.Bool("force", false, "also delete branches with an open pull request or unmerged commits")
+	notify := flag.Bool("notify", false, "instead of deleting, post a grace-period warning comment on each stale branch")
+	reportFormat := flag.String("report-format", "", "write a report in this format: json, csv or markdown (default: no report)")
+	reportOutput := flag.String("report-output", "", "file to write the report to (default: stdout)")
+	concurrency := flag.Int("concurrency", runtime.NumCPU(), "number of repositories to scan in parallel")
+	metricsAddr := flag.String("metrics-addr", "", "address to serve Prometheus-style metrics on, e.g. :9090 (default: disabled)")
+	flag.Parse()
 
-	if err != nil {
-		return nil, err
-	}
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
-	// Check for successful response
-	if resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("failed to fetch repositories: %s", resp.Status())
+	// Load environment variables from the .env file
+	if err := godotenv.Load(); err != nil {
+		logger.Warn("error loading .env file", "error", err)
+		// return
 	}
 
-	return repos, nil
-}
-
-// FetchBranches fetches branches for a specific repository
-func (b *BitbucketClient) FetchBranches(repoSlug string) ([]map[string]interface{}, error) {
-	branches := []map[string]interface{}{}
-	resp, err := b.Client.R().
-		SetAuthToken(b.AuthToken).
-		SetResult(&branches).
-		Get(fmt.Sprintf("%s/repositories/%s/%s/refs/branches", b.BaseURL, b.Workspace, repoSlug))
-
+	// Build the Forge (Bitbucket Cloud or Data Center/Server) selected by
+	// BITBUCKET_KIND, reading the rest of its connection settings from
+	// the environment.
+	forge, err := NewForgeFromEnv()
 	if err != nil {
-		return nil, err
-	}
-
-	// Check for successful response
-	if resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("failed to fetch branches for repo %s: %s", repoSlug, resp.Status())
+		logger.Error("failed to build forge client", "error", err)
+		return
 	}
 
-	return branches, nil
-}
-
-// CheckIfStale checks if a branch is stale based on the last commit date (3 months threshold)
-func (b *BitbucketClient) CheckIfStale(branch map[string]interface{}, threshold time.Duration) (bool, time.Duration) {
-	commitDateStr := branch["target"].(map[string]interface{})["date"].(string)
-	commitDate, err := time.Parse(time.RFC3339, commitDateStr)
+	// Load branch protection and include/exclude filtering rules from
+	// config.yaml (or CONFIG_FILE), with env var overrides.
+	cfg, err := LoadConfigFromEnv()
 	if err != nil {
-		return false, 0
-	}
-	daysSinceCommit := time.Since(commitDate).Hours() / 24
-	if daysSinceCommit > threshold.Hours()/24 {
-		return true, time.Duration(daysSinceCommit) * time.Hour
+		logger.Error("failed to load config", "error", err)
+		return
 	}
-	return false, 0
-}
 
-// DeleteBranch deletes a specific branch in a repository, excluding protected branches
-func (b *BitbucketClient) DeleteBranch(repoSlug, branchName string) error {
-	// List of protected branch names that should not be deleted
-	protectedBranches := []string{"main", "master", "develop"}
-
-	// Check if the branch is protected
-	for _, protectedBranch := range protectedBranches {
-		if branchName == protectedBranch {
-			fmt.Printf("Branch %s is protected and cannot be deleted.\n", branchName)
-			return nil
-		}
+	metrics := &Metrics{}
+	if *metricsAddr != "" {
+		StartMetricsServer(*metricsAddr, metrics, logger)
 	}
 
-	// Proceed with deletion if the branch is not protected
-	resp, err := b.Client.R().
-		SetAuthToken(b.AuthToken).
-		Delete(fmt.Sprintf("%s/repositories/%s/%s/refs/branches/%s", b.BaseURL, b.Workspace, repoSlug, branchName))
-
-	if err != nil {
-		return err
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-	// Check for successful response
-	if resp.StatusCode() != 204 {
-		return fmt.Errorf("failed to delete branch %s: %s", branchName, resp.Status())
+	opts := ScanOptions{
+		Threshold:     3 * 30 * 24 * time.Hour, // 3 months
+		Delete:        *deleteFlag,
+		Force:         *force,
+		Notify:        *notify,
+		NotifyMessage: "This branch has had no activity for a while and is scheduled for cleanup by the stale-branch tool.",
+		Concurrency:   *concurrency,
 	}
 
-	fmt.Printf("Branch %s deleted in repository %s\n", branchName, repoSlug)
-	return nil
-}
+	entries := ListStaleBranches(ctx, forge, cfg, opts, metrics, logger)
 
-// ListStaleBranches lists all stale branches across all repositories in the workspace
-// If delete is true, it will also delete the stale branches, excluding protected branches
-func (b *BitbucketClient) ListStaleBranches(threshold time.Duration, delete bool) {
-	repos, err := b.FetchRepositories()
-	if err != nil {
-		fmt.Printf("Error fetching repositories: %v\n", err)
+	if *reportFormat == "" {
 		return
 	}
 
-	for _, repo := range repos {
-		repoSlug := repo["slug"].(string)
-		fmt.Printf("Checking branches for repository: %s\n", repoSlug)
-
-		branches, err := b.FetchBranches(repoSlug)
+	out := os.Stdout
+	if *reportOutput != "" {
+		f, err := os.Create(*reportOutput)
 		if err != nil {
-			fmt.Printf("Error fetching branches for repo %s: %v\n", repoSlug, err)
-			continue
-		}
-
-		for _, branch := range branches {
-			isStale, nonInteractDays := b.CheckIfStale(branch, threshold)
-			if isStale {
-				fmt.Printf("Stale branch found: %s in repo %s, non-interacted for approximate %.0f days\n", branch["name"], repoSlug, nonInteractDays.Hours()/24)
-				if delete {
-					// if err := b.DeleteBranch(repoSlug, branch["name"].(string)); err != nil {
-					// 	fmt.Printf("Error deleting branch %s: %v\n", branch["name"], err)
-					// }
-					fmt.Printf("Blaaa")
-				}
-			}
+			logger.Error("failed to create report file", "path", *reportOutput, "error", err)
+			return
 		}
+		defer f.Close()
+		out = f
 	}
-}
 
-func main() {
-
-	// Load environment variables from the .env file
-	err := godotenv.Load()
+	reporter, err := NewReporter(*reportFormat, out)
 	if err != nil {
-		fmt.Println("Error loading .env file")
-		// return
-	}
-
-	// Read the Bitbucket token and workspace from environment variables
-	authToken := os.Getenv("BITBUCKET_TOKEN")
-	if authToken == "" {
-		fmt.Println("Error: BITBUCKET_TOKEN environment variable is not set")
+		logger.Error("failed to build reporter", "error", err)
 		return
 	}
 
-	workspace := os.Getenv("BITBUCKET_WORKSPACE")
-	if workspace == "" {
-		fmt.Println("Error: BITBUCKET_WORKSPACE environment variable is not set")
-		return
+	if err := reporter.Write(entries); err != nil {
+		logger.Error("failed to write report", "error", err)
 	}
-
-	// Initialize the Bitbucket client with workspace and auth token
-	client := NewBitbucketClient(workspace, authToken)
-
-	// Define the threshold for stale branches (e.g., 3 months)
-	threshold := 3 * 30 * 24 * time.Hour // 3 months (in hours)
-
-	// Set delete flag to true to delete stale branches
-	delete := false // Change to true to enable deletion
-
-	// List stale branches across all repositories in the workspace, with delete flag
-	client.ListStaleBranches(threshold, delete)
 }
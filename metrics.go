@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+)
+
+// Metrics holds the Prometheus-style counters this tool exposes over
+// --metrics-addr so scheduled cron runs can be monitored.
+type Metrics struct {
+	branchesScanned uint64
+	branchesStale   uint64
+	branchesDeleted uint64
+	apiErrors       uint64
+}
+
+func (m *Metrics) IncScanned()  { atomic.AddUint64(&m.branchesScanned, 1) }
+func (m *Metrics) IncStale()    { atomic.AddUint64(&m.branchesStale, 1) }
+func (m *Metrics) IncDeleted()  { atomic.AddUint64(&m.branchesDeleted, 1) }
+func (m *Metrics) IncAPIError() { atomic.AddUint64(&m.apiErrors, 1) }
+
+// ServeHTTP renders the counters in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# TYPE branches_scanned_total counter\nbranches_scanned_total %d\n", atomic.LoadUint64(&m.branchesScanned))
+	fmt.Fprintf(w, "# TYPE branches_stale_total counter\nbranches_stale_total %d\n", atomic.LoadUint64(&m.branchesStale))
+	fmt.Fprintf(w, "# TYPE branches_deleted_total counter\nbranches_deleted_total %d\n", atomic.LoadUint64(&m.branchesDeleted))
+	fmt.Fprintf(w, "# TYPE api_errors_total counter\napi_errors_total %d\n", atomic.LoadUint64(&m.apiErrors))
+}
+
+// StartMetricsServer starts an HTTP server on addr serving m at /metrics,
+// returning immediately; the server runs until the process exits.
+func StartMetricsServer(addr string, m *Metrics, logger *slog.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			logger.Error("metrics server stopped", "error", err)
+		}
+	}()
+}
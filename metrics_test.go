@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsServeHTTP(t *testing.T) {
+	m := &Metrics{}
+	m.IncScanned()
+	m.IncScanned()
+	m.IncStale()
+	m.IncDeleted()
+	m.IncAPIError()
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"branches_scanned_total 2",
+		"branches_stale_total 1",
+		"branches_deleted_total 1",
+		"api_errors_total 1",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q, got:\n%s", want, body)
+		}
+	}
+}
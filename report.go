@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ReportEntry is one row of a stale-branch scan report.
+type ReportEntry struct {
+	Repo           string    `json:"repo"`
+	Branch         string    `json:"branch"`
+	Author         string    `json:"author"`
+	LastCommitDate time.Time `json:"last_commit_date"`
+	DaysIdle       int       `json:"days_idle"`
+	Action         string    `json:"action"` // "deleted", "notified", "reported"
+
+	// HasOpenPR and HasUnmergedCommits are independent signals: a branch
+	// can have both at once, which SkipReason alone can't represent.
+	HasOpenPR          bool   `json:"has_open_pr"`
+	HasUnmergedCommits bool   `json:"has_unmerged_commits"`
+	SkipReason         string `json:"skip_reason"` // "protected", "open_pr", "unmerged_commits", "open_pr,unmerged_commits", ""
+}
+
+// Reporter writes a completed stale-branch report to a sink.
+type Reporter interface {
+	Write(entries []ReportEntry) error
+}
+
+// NewReporter returns the Reporter for format ("json", "csv" or
+// "markdown"), writing to w.
+func NewReporter(format string, w io.Writer) (Reporter, error) {
+	switch format {
+	case "json":
+		return &jsonReporter{w: w}, nil
+	case "csv":
+		return &csvReporter{w: w}, nil
+	case "markdown":
+		return &markdownReporter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported report format %q: expected json, csv or markdown", format)
+	}
+}
+
+type jsonReporter struct{ w io.Writer }
+
+func (r *jsonReporter) Write(entries []ReportEntry) error {
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+type csvReporter struct{ w io.Writer }
+
+func (r *csvReporter) Write(entries []ReportEntry) error {
+	cw := csv.NewWriter(r.w)
+	header := []string{"repo", "branch", "author", "last_commit_date", "days_idle", "action", "has_open_pr", "has_unmerged_commits", "skip_reason"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		row := []string{
+			e.Repo,
+			e.Branch,
+			e.Author,
+			e.LastCommitDate.Format(time.RFC3339),
+			fmt.Sprintf("%d", e.DaysIdle),
+			e.Action,
+			strconv.FormatBool(e.HasOpenPR),
+			strconv.FormatBool(e.HasUnmergedCommits),
+			e.SkipReason,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type markdownReporter struct{ w io.Writer }
+
+func (r *markdownReporter) Write(entries []ReportEntry) error {
+	if _, err := fmt.Fprintln(r.w, "| Repo | Branch | Author | Last Commit | Days Idle | Action | Open PR | Unmerged Commits | Skip Reason |"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(r.w, "|------|--------|--------|-------------|-----------|--------|---------|-------------------|-------------|"); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		skipReason := e.SkipReason
+		if skipReason == "" {
+			skipReason = "-"
+		}
+		if _, err := fmt.Fprintf(r.w, "| %s | %s | %s | %s | %d | %s | %t | %t | %s |\n",
+			e.Repo, e.Branch, e.Author, e.LastCommitDate.Format(time.RFC3339), e.DaysIdle, e.Action,
+			e.HasOpenPR, e.HasUnmergedCommits, skipReason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleEntries() []ReportEntry {
+	return []ReportEntry{
+		{
+			Repo:           "team/repo",
+			Branch:         "feature/old",
+			Author:         "Jane Doe",
+			LastCommitDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			DaysIdle:       120,
+			Action:         "deleted",
+		},
+		{
+			Repo:           "team/repo",
+			Branch:         "release/1.0",
+			Author:         "John Doe",
+			LastCommitDate: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			DaysIdle:       90,
+			Action:         "reported",
+			SkipReason:     "protected",
+		},
+		{
+			Repo:               "team/repo",
+			Branch:             "feature/both-signals",
+			Author:             "Jane Doe",
+			LastCommitDate:     time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			DaysIdle:           100,
+			Action:             "reported",
+			HasOpenPR:          true,
+			HasUnmergedCommits: true,
+			SkipReason:         "open_pr,unmerged_commits",
+		},
+	}
+}
+
+func TestNewReporterUnsupportedFormat(t *testing.T) {
+	if _, err := NewReporter("yaml", &bytes.Buffer{}); err == nil {
+		t.Fatal("NewReporter(\"yaml\", ...) error = nil, want error for unsupported format")
+	}
+}
+
+func TestJSONReporterWrite(t *testing.T) {
+	var buf bytes.Buffer
+	reporter, err := NewReporter("json", &buf)
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+	if err := reporter.Write(sampleEntries()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var got []ReportEntry
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode JSON output: %v", err)
+	}
+	if len(got) != 3 || got[1].SkipReason != "protected" {
+		t.Errorf("decoded entries = %+v, want 3 entries with second SkipReason=protected", got)
+	}
+	if !got[2].HasOpenPR || !got[2].HasUnmergedCommits || got[2].SkipReason != "open_pr,unmerged_commits" {
+		t.Errorf("third entry = %+v, want both HasOpenPR and HasUnmergedCommits set with a combined SkipReason", got[2])
+	}
+}
+
+func TestCSVReporterWrite(t *testing.T) {
+	var buf bytes.Buffer
+	reporter, err := NewReporter("csv", &buf)
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+	if err := reporter.Write(sampleEntries()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV output: %v", err)
+	}
+	if len(rows) != 4 { // header + 3 entries
+		t.Fatalf("got %d rows, want 4 (header + 3 entries)", len(rows))
+	}
+	if rows[0][0] != "repo" || rows[0][6] != "has_open_pr" || rows[0][7] != "has_unmerged_commits" || rows[0][8] != "skip_reason" {
+		t.Errorf("header = %v, want repo...has_open_pr, has_unmerged_commits, skip_reason columns", rows[0])
+	}
+	if rows[2][8] != "protected" {
+		t.Errorf("second row skip_reason = %q, want %q", rows[2][8], "protected")
+	}
+	if rows[3][6] != "true" || rows[3][7] != "true" || rows[3][8] != "open_pr,unmerged_commits" {
+		t.Errorf("third row = %v, want has_open_pr=true, has_unmerged_commits=true, skip_reason=open_pr,unmerged_commits", rows[3])
+	}
+}
+
+func TestMarkdownReporterWrite(t *testing.T) {
+	var buf bytes.Buffer
+	reporter, err := NewReporter("markdown", &buf)
+	if err != nil {
+		t.Fatalf("NewReporter() error = %v", err)
+	}
+	if err := reporter.Write(sampleEntries()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "| Repo | Branch |") {
+		t.Errorf("output missing table header: %q", out)
+	}
+	if !strings.Contains(out, "release/1.0") || !strings.Contains(out, "protected") {
+		t.Errorf("output missing expected entry data: %q", out)
+	}
+	if !strings.Contains(out, " - |") {
+		t.Errorf("output missing \"-\" placeholder for empty skip_reason: %q", out)
+	}
+	if !strings.Contains(out, "| true | true | open_pr,unmerged_commits |") {
+		t.Errorf("output missing row with both open-PR and unmerged-commits signals: %q", out)
+	}
+}
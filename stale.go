@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScanOptions controls how ListStaleBranches treats the stale branches it
+// finds.
+type ScanOptions struct {
+	Threshold time.Duration
+
+	// Delete causes stale branches to actually be deleted (subject to
+	// Force below). When false, branches are only reported.
+	Delete bool
+
+	// Force also deletes branches that have an open pull request or
+	// unmerged commits, instead of skipping them.
+	Force bool
+
+	// Notify posts a grace-period warning comment on each stale branch's
+	// last commit instead of deleting it.
+	Notify bool
+
+	// NotifyMessage is the comment body posted when Notify is set.
+	NotifyMessage string
+
+	// Concurrency is how many repositories are scanned in parallel.
+	// Defaults to 1 when left at zero.
+	Concurrency int
+}
+
+// CheckIfStale reports whether branch's last commit is older than threshold,
+// returning how long it's been idle for.
+func CheckIfStale(branch Branch, threshold time.Duration) (bool, time.Duration) {
+	idleFor := time.Since(branch.LastCommitDate)
+	if idleFor > threshold {
+		return true, idleFor
+	}
+	return false, 0
+}
+
+// protectedPatterns returns cfg's configured protected_branches merged with
+// any server-side branch restrictions forge can report for repoSlug.
+func protectedPatterns(forge Forge, repoSlug string, cfg Config, logger *slog.Logger) []string {
+	patterns := cfg.ProtectedBranches
+	if lister, ok := forge.(BranchRestrictionLister); ok {
+		restricted, err := lister.ListProtectedBranches(repoSlug)
+		if err != nil {
+			logger.Warn("failed to fetch branch restrictions", "repo", repoSlug, "error", err)
+		} else {
+			patterns = append(append([]string{}, patterns...), restricted...)
+		}
+	}
+	return patterns
+}
+
+// BranchClassification reports everything ListStaleBranches learned about a
+// branch before deciding whether it's safe to delete.
+type BranchClassification struct {
+	Branch             Branch
+	IsStale            bool
+	IdleFor            time.Duration
+	HasOpenPR          bool
+	HasUnmergedCommits bool
+}
+
+// ClassifyBranch reports whether branch is stale and, if so, whether
+// deleting it would lose work: an open pull request still references it,
+// or it has commits that were never merged into defaultBranch.
+func ClassifyBranch(forge Forge, repoSlug string, branch Branch, defaultBranch string, threshold time.Duration) (BranchClassification, error) {
+	isStale, idleFor := CheckIfStale(branch, threshold)
+	cls := BranchClassification{Branch: branch, IsStale: isStale, IdleFor: idleFor}
+	if !isStale {
+		return cls, nil
+	}
+
+	hasPR, err := forge.HasOpenPullRequest(repoSlug, branch.Name)
+	if err != nil {
+		return cls, fmt.Errorf("checking open pull requests for branch %s: %w", branch.Name, err)
+	}
+	cls.HasOpenPR = hasPR
+
+	if defaultBranch != "" && branch.Name != defaultBranch {
+		ahead, err := forge.CommitsAheadOfDefault(repoSlug, branch.Name, defaultBranch)
+		if err != nil {
+			return cls, fmt.Errorf("checking commits ahead of %s for branch %s: %w", defaultBranch, branch.Name, err)
+		}
+		cls.HasUnmergedCommits = ahead > 0
+	}
+
+	return cls, nil
+}
+
+// scanRepo scans a single repository for stale branches, applying cfg's
+// branch filters and opts' delete/notify behavior, and returns one
+// ReportEntry per stale branch found.
+func scanRepo(ctx context.Context, forge Forge, cfg Config, opts ScanOptions, metrics *Metrics, logger *slog.Logger, repo Repository) []ReportEntry {
+	var entries []ReportEntry
+
+	logger.Info("checking branches for repository", "repo", repo.Slug)
+
+	branches, err := forge.FetchBranches(repo.Slug)
+	if err != nil {
+		logger.Error("failed to fetch branches", "repo", repo.Slug, "error", err)
+		metrics.IncAPIError()
+		return entries
+	}
+
+	protected := protectedPatterns(forge, repo.Slug, cfg, logger)
+
+	for _, branch := range branches {
+		if ctx.Err() != nil {
+			return entries
+		}
+		if !isAllowed(branch.Name, cfg.IncludeBranches, cfg.ExcludeBranches) {
+			continue
+		}
+
+		metrics.IncScanned()
+
+		if matchesAny(protected, branch.Name) {
+			isStale, idleFor := CheckIfStale(branch, opts.Threshold)
+			if !isStale {
+				continue
+			}
+			metrics.IncStale()
+
+			daysIdle := int(idleFor.Hours() / 24)
+			logger.Info("stale branch found but protected",
+				"repo", repo.Slug, "branch", branch.Name, "days_idle", daysIdle)
+
+			entries = append(entries, ReportEntry{
+				Repo:           repo.Slug,
+				Branch:         branch.Name,
+				Author:         branch.Author,
+				LastCommitDate: branch.LastCommitDate,
+				DaysIdle:       daysIdle,
+				Action:         "reported",
+				SkipReason:     "protected",
+			})
+			continue
+		}
+
+		cls, err := ClassifyBranch(forge, repo.Slug, branch, repo.MainBranch, opts.Threshold)
+		if err != nil {
+			logger.Error("failed to classify branch", "repo", repo.Slug, "branch", branch.Name, "error", err)
+			metrics.IncAPIError()
+			continue
+		}
+		if !cls.IsStale {
+			continue
+		}
+		metrics.IncStale()
+
+		daysIdle := int(cls.IdleFor.Hours() / 24)
+		logger.Info("stale branch found",
+			"repo", repo.Slug, "branch", branch.Name, "days_idle", daysIdle,
+			"open_pr", cls.HasOpenPR, "unmerged_commits", cls.HasUnmergedCommits)
+
+		entry := ReportEntry{
+			Repo:               repo.Slug,
+			Branch:             branch.Name,
+			Author:             branch.Author,
+			LastCommitDate:     branch.LastCommitDate,
+			DaysIdle:           daysIdle,
+			Action:             "reported",
+			HasOpenPR:          cls.HasOpenPR,
+			HasUnmergedCommits: cls.HasUnmergedCommits,
+		}
+
+		unsafeToDelete := cls.HasOpenPR || cls.HasUnmergedCommits
+		if unsafeToDelete && !opts.Force {
+			var reasons []string
+			if cls.HasOpenPR {
+				reasons = append(reasons, "open_pr")
+			}
+			if cls.HasUnmergedCommits {
+				reasons = append(reasons, "unmerged_commits")
+			}
+			entry.SkipReason = strings.Join(reasons, ",")
+		}
+
+		switch {
+		case opts.Notify:
+			message := fmt.Sprintf("%s (idle for %d days)", opts.NotifyMessage, daysIdle)
+			if err := forge.NotifyStaleBranch(repo.Slug, branch, message); err != nil {
+				logger.Error("failed to notify branch", "repo", repo.Slug, "branch", branch.Name, "error", err)
+				metrics.IncAPIError()
+			} else {
+				entry.Action = "notified"
+			}
+		case opts.Delete:
+			if entry.SkipReason != "" {
+				logger.Info("skipping delete: unsafe without --force", "repo", repo.Slug, "branch", branch.Name, "reason", entry.SkipReason)
+			} else if err := forge.DeleteBranch(repo.Slug, branch.Name); err != nil {
+				logger.Error("failed to delete branch", "repo", repo.Slug, "branch", branch.Name, "error", err)
+				metrics.IncAPIError()
+			} else {
+				logger.Info("branch deleted", "repo", repo.Slug, "branch", branch.Name)
+				entry.Action = "deleted"
+				metrics.IncDeleted()
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// ListStaleBranches fans repository scans out across opts.Concurrency
+// workers, stopping early if ctx is canceled (e.g. on SIGINT), and returns
+// one ReportEntry per stale branch found so callers can feed it to a
+// Reporter. Branches matching cfg's protected_branches (including
+// server-detected restrictions) are skipped entirely.
+func ListStaleBranches(ctx context.Context, forge Forge, cfg Config, opts ScanOptions, metrics *Metrics, logger *slog.Logger) []ReportEntry {
+	repos, err := forge.FetchRepositories()
+	if err != nil {
+		logger.Error("failed to fetch repositories", "error", err)
+		metrics.IncAPIError()
+		return nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var allowedRepos []Repository
+	for _, repo := range repos {
+		if isAllowed(repo.Slug, cfg.IncludeRepos, cfg.ExcludeRepos) {
+			allowedRepos = append(allowedRepos, repo)
+		}
+	}
+
+	repoCh := make(chan Repository)
+	resultCh := make(chan []ReportEntry)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range repoCh {
+				resultCh <- scanRepo(ctx, forge, cfg, opts, metrics, logger, repo)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(repoCh)
+		for _, repo := range allowedRepos {
+			select {
+			case repoCh <- repo:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	var entries []ReportEntry
+	for result := range resultCh {
+		entries = append(entries, result...)
+	}
+
+	// Workers finish in whatever order their API calls complete, so sort
+	// for a deterministic report regardless of scan concurrency.
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Repo != entries[j].Repo {
+			return entries[i].Repo < entries[j].Repo
+		}
+		return entries[i].Branch < entries[j].Branch
+	})
+
+	return entries
+}
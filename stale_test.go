@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// fakeForge is a minimal Forge stub for exercising ClassifyBranch and
+// ListStaleBranches without hitting a real Bitbucket API.
+type fakeForge struct {
+	hasOpenPR  bool
+	prErr      error
+	aheadCount int
+	aheadErr   error
+
+	repos          []Repository
+	branchesByRepo map[string][]Branch
+}
+
+func (f *fakeForge) FetchRepositories() ([]Repository, error) { return f.repos, nil }
+func (f *fakeForge) FetchBranches(repoSlug string) ([]Branch, error) {
+	return f.branchesByRepo[repoSlug], nil
+}
+func (f *fakeForge) LastCommitDate(string, string) (time.Time, error) {
+	return time.Time{}, nil
+}
+func (f *fakeForge) DeleteBranch(string, string) error { return nil }
+func (f *fakeForge) HasOpenPullRequest(string, string) (bool, error) {
+	return f.hasOpenPR, f.prErr
+}
+func (f *fakeForge) CommitsAheadOfDefault(string, string, string) (int, error) {
+	return f.aheadCount, f.aheadErr
+}
+func (f *fakeForge) NotifyStaleBranch(string, Branch, string) error { return nil }
+
+func TestCheckIfStale(t *testing.T) {
+	threshold := 90 * 24 * time.Hour
+
+	stale := Branch{LastCommitDate: time.Now().Add(-100 * 24 * time.Hour)}
+	if isStale, idleFor := CheckIfStale(stale, threshold); !isStale || idleFor <= threshold {
+		t.Errorf("CheckIfStale(stale) = (%v, %v), want stale with idleFor > threshold", isStale, idleFor)
+	}
+
+	fresh := Branch{LastCommitDate: time.Now().Add(-1 * time.Hour)}
+	if isStale, idleFor := CheckIfStale(fresh, threshold); isStale || idleFor != 0 {
+		t.Errorf("CheckIfStale(fresh) = (%v, %v), want (false, 0)", isStale, idleFor)
+	}
+}
+
+func TestClassifyBranchNotStale(t *testing.T) {
+	forge := &fakeForge{}
+	branch := Branch{Name: "feature/x", LastCommitDate: time.Now()}
+
+	cls, err := ClassifyBranch(forge, "repo", branch, "main", 90*24*time.Hour)
+	if err != nil {
+		t.Fatalf("ClassifyBranch() error = %v", err)
+	}
+	if cls.IsStale {
+		t.Errorf("IsStale = true, want false for a fresh branch")
+	}
+}
+
+func TestClassifyBranchStaleWithOpenPR(t *testing.T) {
+	forge := &fakeForge{hasOpenPR: true}
+	branch := Branch{Name: "feature/x", LastCommitDate: time.Now().Add(-100 * 24 * time.Hour)}
+
+	cls, err := ClassifyBranch(forge, "repo", branch, "main", 90*24*time.Hour)
+	if err != nil {
+		t.Fatalf("ClassifyBranch() error = %v", err)
+	}
+	if !cls.IsStale {
+		t.Fatalf("IsStale = false, want true")
+	}
+	if !cls.HasOpenPR {
+		t.Errorf("HasOpenPR = false, want true")
+	}
+}
+
+func TestClassifyBranchStaleWithUnmergedCommits(t *testing.T) {
+	forge := &fakeForge{aheadCount: 3}
+	branch := Branch{Name: "feature/x", LastCommitDate: time.Now().Add(-100 * 24 * time.Hour)}
+
+	cls, err := ClassifyBranch(forge, "repo", branch, "main", 90*24*time.Hour)
+	if err != nil {
+		t.Fatalf("ClassifyBranch() error = %v", err)
+	}
+	if !cls.HasUnmergedCommits {
+		t.Errorf("HasUnmergedCommits = false, want true")
+	}
+}
+
+func TestClassifyBranchSkipsAheadCheckForDefaultBranch(t *testing.T) {
+	forge := &fakeForge{aheadCount: 5}
+	branch := Branch{Name: "main", LastCommitDate: time.Now().Add(-100 * 24 * time.Hour)}
+
+	cls, err := ClassifyBranch(forge, "repo", branch, "main", 90*24*time.Hour)
+	if err != nil {
+		t.Fatalf("ClassifyBranch() error = %v", err)
+	}
+	if cls.HasUnmergedCommits {
+		t.Errorf("HasUnmergedCommits = true, want false when branch is the default branch")
+	}
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestListStaleBranchesScansAcrossRepos(t *testing.T) {
+	stale := Branch{Name: "feature/old", LastCommitDate: time.Now().Add(-100 * 24 * time.Hour)}
+	forge := &fakeForge{
+		repos: []Repository{
+			{Slug: "repo-c", MainBranch: "main"},
+			{Slug: "repo-a", MainBranch: "main"},
+			{Slug: "repo-b", MainBranch: "main"},
+		},
+		branchesByRepo: map[string][]Branch{
+			"repo-a": {stale},
+			"repo-b": {stale},
+			"repo-c": {stale},
+		},
+	}
+	opts := ScanOptions{Threshold: 90 * 24 * time.Hour, Concurrency: 2}
+
+	entries := ListStaleBranches(context.Background(), forge, Config{}, opts, &Metrics{}, discardLogger())
+
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3 (one per repo)", len(entries))
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i-1].Repo > entries[i].Repo {
+			t.Errorf("entries not sorted by repo: %+v", entries)
+		}
+	}
+}
+
+func TestListStaleBranchesStopsOnCanceledContext(t *testing.T) {
+	stale := Branch{Name: "feature/old", LastCommitDate: time.Now().Add(-100 * 24 * time.Hour)}
+	forge := &fakeForge{
+		repos: []Repository{
+			{Slug: "repo-a", MainBranch: "main"},
+			{Slug: "repo-b", MainBranch: "main"},
+		},
+		branchesByRepo: map[string][]Branch{
+			"repo-a": {stale},
+			"repo-b": {stale},
+		},
+	}
+	opts := ScanOptions{Threshold: 90 * 24 * time.Hour, Concurrency: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	entries := ListStaleBranches(ctx, forge, Config{}, opts, &Metrics{}, discardLogger())
+
+	if len(entries) != 0 {
+		t.Errorf("got %d entries, want 0 once the context is canceled before scanning starts", len(entries))
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import "strings"
+
+// splitAndTrim splits s on sep, trims whitespace from each part and drops
+// empty entries. Used to parse comma-separated env vars such as
+// BITBUCKET_PROJECTS.
+func splitAndTrim(s, sep string) []string {
+	parts := strings.Split(s, sep)
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}